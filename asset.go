@@ -0,0 +1,52 @@
+package vfsgen
+
+import "time"
+
+// dir holds the metadata for a directory asset collected by findFiles,
+// prior to code generation.
+type dir struct {
+	name    string
+	entries []string
+	modTime time.Time
+}
+
+// compressedFile holds the metadata for a file asset that will be
+// embedded gzip-compressed, collected by findFiles prior to code
+// generation.
+type compressedFile struct {
+	name             string
+	uncompressedSize int64
+	modTime          time.Time
+
+	// compressRatio is the gzip compressed/uncompressed ratio measured by
+	// probeCompressRatio, kept around so writeAssets can note it in the
+	// generated code.
+	compressRatio float64
+}
+
+// rawFile holds the metadata for a file asset that compresses poorly and
+// will instead be embedded verbatim, collected by findFiles prior to
+// code generation.
+type rawFile struct {
+	name    string
+	size    int64
+	modTime time.Time
+
+	// compressRatio is the gzip compressed/uncompressed ratio measured by
+	// probeCompressRatio, kept around so writeAssets can note it in the
+	// generated code.
+	compressRatio float64
+}
+
+// encodedFile holds the metadata for a file asset whose compression was
+// chosen explicitly via Config.Compressor or Config.CompressorFunc,
+// collected by findFiles prior to code generation. Unlike compressedFile
+// and rawFile, which findFiles picks between itself using MinCompressRatio,
+// an encodedFile's bytes are produced by running compressor.Encode over
+// the source content.
+type encodedFile struct {
+	name             string
+	uncompressedSize int64
+	modTime          time.Time
+	compressor       Compressor
+}
@@ -0,0 +1,62 @@
+package vfsgen
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"time"
+)
+
+// probeCompressRatio gzips a sample of the file at path (its first 4KiB,
+// or the whole file if smaller) and returns the ratio of compressed to
+// uncompressed sample size. Callers use the ratio to decide whether a
+// file is worth gzip-compressing at all.
+func probeCompressRatio(fs http.FileSystem, path string, size int64) (float64, error) {
+	const probeSize = 4096
+
+	n := int64(probeSize)
+	if size < n {
+		n = size
+	}
+	if n == 0 {
+		return 0, nil
+	}
+
+	f, err := fs.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	sample := make([]byte, n)
+	if _, err := io.ReadFull(f, sample); err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(sample); err != nil {
+		return 0, err
+	}
+	if err := gz.Close(); err != nil {
+		return 0, err
+	}
+
+	return float64(buf.Len()) / float64(len(sample)), nil
+}
+
+// newGzipWriter returns a gzip.Writer for w. When reproducible is true, the
+// gzip header is scrubbed of anything that would make the output depend on
+// when or where it was generated: no name, no comment, no modification
+// time, and an OS byte of 0xff ("unknown"), per RFC 1952.
+func newGzipWriter(w io.Writer, reproducible bool) *gzip.Writer {
+	gz := gzip.NewWriter(w)
+	if reproducible {
+		gz.Name = ""
+		gz.Comment = ""
+		gz.ModTime = time.Time{}
+		gz.OS = 0xff
+	}
+	return gz
+}
@@ -0,0 +1,130 @@
+package vfsgen
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compressor is a pluggable compression backend for generated asset
+// content. Config.Compressor (or Config.CompressorFunc) selects one per
+// file; vfsgen ships GzipCompressor, ZstdCompressor, BrotliCompressor and
+// NoCompression, trading binary size against decode speed without
+// requiring a fork of the generator.
+type Compressor interface {
+	// Encode returns a WriteCloser that compresses bytes written to it
+	// into w. Closing it must flush and finalize the stream.
+	Encode(w io.Writer) io.WriteCloser
+
+	// Extension is a short, human-readable tag for the format (e.g.
+	// "gzip", "zstd", "br", "none"), used in generated comments for
+	// auditability.
+	Extension() string
+
+	// RuntimeDecoder names the built-in decode strategy the generated
+	// Open method should dispatch to. It must be one of the identifiers
+	// recognized by encodingIdent ("gzip", "zstd", "br" or "none");
+	// vfsgen has no way to emit decode logic for anything else.
+	RuntimeDecoder() string
+}
+
+// GzipCompressor compresses with compress/gzip, at Level (see
+// gzip.NewWriterLevel). A zero Level means gzip.DefaultCompression.
+type GzipCompressor struct {
+	Level int
+}
+
+func (c GzipCompressor) Encode(w io.Writer) io.WriteCloser {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	gw, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		panic("vfsgen: invalid GzipCompressor.Level: " + err.Error())
+	}
+	return gw
+}
+func (c GzipCompressor) Extension() string      { return "gzip" }
+func (c GzipCompressor) RuntimeDecoder() string { return "gzip" }
+
+// ZstdCompressor compresses with github.com/klauspost/compress/zstd. It's
+// a good default for large blobs: a compression ratio close to gzip's at
+// substantially faster decode speed.
+type ZstdCompressor struct{}
+
+func (c ZstdCompressor) Encode(w io.Writer) io.WriteCloser {
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		panic("vfsgen: zstd.NewWriter: " + err.Error())
+	}
+	return zw
+}
+func (c ZstdCompressor) Extension() string      { return "zstd" }
+func (c ZstdCompressor) RuntimeDecoder() string { return "zstd" }
+
+// BrotliCompressor compresses with github.com/andybalholm/brotli, at
+// Quality (see brotli.NewWriterLevel). A zero Quality means
+// brotli.DefaultCompression. Brotli typically compresses text (HTML, CSS,
+// JS, templates) smaller than gzip, at a higher CPU cost during
+// generation.
+type BrotliCompressor struct {
+	Quality int
+}
+
+func (c BrotliCompressor) Encode(w io.Writer) io.WriteCloser {
+	quality := c.Quality
+	if quality == 0 {
+		quality = brotli.DefaultCompression
+	}
+	return brotli.NewWriterLevel(w, quality)
+}
+func (c BrotliCompressor) Extension() string      { return "br" }
+func (c BrotliCompressor) RuntimeDecoder() string { return "br" }
+
+// NoCompression stores content verbatim. Use it for assets that are
+// already compressed (images, fonts, archives) or too small and
+// latency-sensitive to be worth a decode step.
+type NoCompression struct{}
+
+func (c NoCompression) Encode(w io.Writer) io.WriteCloser { return nopWriteCloser{w} }
+func (c NoCompression) Extension() string                { return "none" }
+func (c NoCompression) RuntimeDecoder() string            { return "none" }
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// encodingIdent maps a Compressor's RuntimeDecoder() to the Go identifier
+// suffix (e.g. "Gzip" for the generated constant encodingGzip) that
+// writeAssets/writeAssetsFS bake into the generated code.
+func encodingIdent(runtimeDecoder string) (string, error) {
+	switch runtimeDecoder {
+	case "gzip":
+		return "Gzip", nil
+	case "zstd":
+		return "Zstd", nil
+	case "br":
+		return "Brotli", nil
+	case "none":
+		return "None", nil
+	default:
+		return "", fmt.Errorf("vfsgen: Compressor.RuntimeDecoder() returned unrecognized value %q", runtimeDecoder)
+	}
+}
+
+// encodingsUsed returns the set of RuntimeDecoder values among toc's
+// encodedFile assets, so the generated file can import and emit decode
+// logic for only the encodings it actually contains.
+func encodingsUsed(toc []pathAsset) map[string]bool {
+	used := make(map[string]bool)
+	for _, pathAsset := range toc {
+		if ef, ok := pathAsset.asset.(*encodedFile); ok {
+			used[ef.compressor.RuntimeDecoder()] = true
+		}
+	}
+	return used
+}
@@ -0,0 +1,123 @@
+package vfsgen
+
+import (
+	"fmt"
+	"os"
+	pathpkg "path"
+	"strconv"
+	"time"
+)
+
+// Config is the configuration for Translate.
+type Config struct {
+	// Input is the list of source filesystem trees to merge into the
+	// generated virtual filesystem. At least one is required.
+	Input []InputConfig
+
+	// Output is the path to the generated Go source file. If left empty,
+	// it defaults to "{package}_vfsdata.go".
+	Output string
+
+	// Package is the name of the package in the generated code. If left
+	// empty, it defaults to "main".
+	Package string
+
+	// Tags are the optional build tags in the generated code. If left
+	// empty, no build tags are specified.
+	Tags string
+
+	// FSMode, if true, generates an io/fs.FS-based virtual filesystem
+	// (also implementing fs.ReadDirFS, fs.ReadFileFS, fs.StatFS,
+	// fs.SubFS and fs.GlobFS) instead of the default http.FileSystem.
+	FSMode bool
+
+	// MinCompressRatio is the compressed/uncompressed ratio, measured on
+	// a sample of each file, above which gzip compression is skipped in
+	// favor of storing the file's bytes verbatim. It guards against
+	// paying gzip's CPU cost at build and runtime for already-compressed
+	// formats like PNG, JPEG, woff2 and zip. If left zero, it defaults
+	// to 0.97.
+	MinCompressRatio float64
+
+	// DevOutput, if set, additionally generates a "dev" build-tagged
+	// companion file at this path that serves assets straight off disk
+	// instead of from the embedded copy, so they can be edited without
+	// re-running go generate. The file generated at Output gains the
+	// complementary "!dev" build tag. DevOutput requires every
+	// InputConfig.FS to be an http.Dir, so its on-disk path can be
+	// recorded.
+	DevOutput string
+
+	// Reproducible, if true, makes the generated file byte-for-byte
+	// identical across machines and runs: the table of contents is
+	// sorted by path, every embedded modTime is clamped to
+	// SourceDateEpoch, and the gzip header carries no name, modTime or
+	// OS byte.
+	Reproducible bool
+
+	// SourceDateEpoch is the modTime recorded for every asset when
+	// Reproducible is true. If left zero, it defaults to the value of
+	// the $SOURCE_DATE_EPOCH environment variable (Unix seconds), or
+	// the Unix epoch if that isn't set either.
+	SourceDateEpoch time.Time
+
+	// Compressor, if set, replaces the default gzip-or-raw heuristic
+	// (gzip unless MinCompressRatio says otherwise) with an explicit
+	// compression backend applied to every file. CompressorFunc, where
+	// set, takes precedence on a per-file basis.
+	Compressor Compressor
+
+	// CompressorFunc, if set, selects a Compressor for each file by its
+	// mounted path and uncompressed size. It takes precedence over
+	// Compressor; returning nil for a given file falls back to
+	// Compressor (or the default heuristic, if Compressor is also nil).
+	CompressorFunc func(path string, size int64) Compressor
+}
+
+// validate ensures the configuration is valid, returning an error that
+// describes the problem, if any. It fills in defaults for fields that
+// were left zero.
+func (c *Config) validate() error {
+	if len(c.Input) == 0 {
+		return fmt.Errorf("Config.Input must have at least one entry")
+	}
+
+	prefixes := make(map[string]bool, len(c.Input))
+	for i := range c.Input {
+		ic := &c.Input[i]
+		if ic.FS == nil {
+			return fmt.Errorf("Config.Input[%d].FS must be set", i)
+		}
+		if ic.Prefix == "" {
+			ic.Prefix = "/"
+		}
+		ic.Prefix = pathpkg.Clean("/" + ic.Prefix)
+		if prefixes[ic.Prefix] {
+			return fmt.Errorf("Config.Input has more than one entry mounted at prefix %q", ic.Prefix)
+		}
+		prefixes[ic.Prefix] = true
+	}
+
+	if c.Package == "" {
+		c.Package = "main"
+	}
+	if c.Output == "" {
+		c.Output = fmt.Sprintf("%s_vfsdata.go", c.Package)
+	}
+	if c.MinCompressRatio == 0 {
+		c.MinCompressRatio = 0.97
+	}
+
+	if c.Reproducible && c.SourceDateEpoch.IsZero() {
+		c.SourceDateEpoch = time.Unix(0, 0).UTC()
+		if raw := os.Getenv("SOURCE_DATE_EPOCH"); raw != "" {
+			secs, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return fmt.Errorf("vfsgen: invalid $SOURCE_DATE_EPOCH %q: %v", raw, err)
+			}
+			c.SourceDateEpoch = time.Unix(secs, 0).UTC()
+		}
+	}
+
+	return nil
+}
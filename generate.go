@@ -2,16 +2,17 @@ package vfsgen
 
 import (
 	"bufio"
-	"compress/gzip"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	pathpkg "path"
+	"path/filepath"
 	"sort"
+	"time"
 
-	"github.com/shurcooL/go/vfs/httpfs/vfsutil"
+	"github.com/shurcooL/httpfs/vfsutil"
 )
 
 // Translate reads assets from an input directory, converts them
@@ -26,11 +27,17 @@ func Translate(c *Config) error {
 
 	// Locate all the assets.
 	var toc []pathAsset
-	err = findFiles(c.Input, &toc)
+	err = findFiles(c, &toc)
 	if err != nil {
 		return err
 	}
 
+	// A reproducible build can't depend on the order the host filesystem
+	// happened to return entries in.
+	if c.Reproducible {
+		sort.Slice(toc, func(i, j int) bool { return toc[i].path < toc[j].path })
+	}
+
 	// Create output file.
 	f, err := os.Create(c.Output)
 	if err != nil {
@@ -48,8 +55,25 @@ func Translate(c *Config) error {
 		return err
 	}
 
-	// Write build tags, if applicable.
-	if c.Tags != "" {
+	// Write build tags, if applicable. When a dev companion file is also
+	// being generated, this file is the release half of the pair, so it
+	// must carry the complementary "!dev" tag.
+	if c.DevOutput != "" {
+		_, err = fmt.Fprintf(buf, "// +build !dev\n")
+		if err != nil {
+			return err
+		}
+		if c.Tags != "" {
+			_, err = fmt.Fprintf(buf, "// +build %s\n", c.Tags)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(buf, "\n")
+		if err != nil {
+			return err
+		}
+	} else if c.Tags != "" {
 		_, err = fmt.Fprintf(buf, "// +build %s\n\n", c.Tags)
 		if err != nil {
 			return err
@@ -62,91 +86,248 @@ func Translate(c *Config) error {
 		return err
 	}
 
-	// Write assets.
-	err = writeAssets(buf, c, toc)
-	if err != nil {
-		return err
+	// Write assets and the virtual file system, in the mode selected by
+	// the configuration.
+	encodings := encodingsUsed(toc)
+	if c.FSMode {
+		err = writeAssetsFS(buf, c, toc, encodings)
+		if err != nil {
+			return err
+		}
+		err = writeVFSFS(buf, encodings)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = writeAssets(buf, c, toc, encodings)
+		if err != nil {
+			return err
+		}
+
+		err = writeVFS(buf, encodings)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Write virtual file system.
-	err = writeVFS(buf)
-	if err != nil {
-		return err
+	if c.DevOutput != "" {
+		return writeDevFile(c)
 	}
 
 	return nil
 }
 
-// readDirPaths reads the directory named by dirname and returns
-// a sorted list of directory paths.
-func readDirPaths(fs http.FileSystem, dirname string) ([]string, error) {
-	fis, err := vfsutil.ReadDir(fs, dirname)
+// readDirPaths reads the directory named by dirname and returns a sorted
+// list of its mounted child paths, honoring ic.Recursive and
+// ic.Include/Exclude.
+func readDirPaths(ic *InputConfig, dirname string) ([]string, error) {
+	fis, err := vfsutil.ReadDir(ic.FS, dirname)
 	if err != nil {
 		return nil, err
 	}
-	paths := make([]string, len(fis))
-	for i := range fis {
-		paths[i] = pathpkg.Join(dirname, fis[i].Name())
+	var paths []string
+	for _, fi := range fis {
+		if !ic.includeAsset(fi.Name()) {
+			continue
+		}
+		if fi.IsDir() && !ic.Recursive {
+			continue
+		}
+		paths = append(paths, mountPath(ic.Prefix, pathpkg.Join(dirname, fi.Name())))
 	}
 	sort.Strings(paths)
 	return paths, nil
 }
 
-// findFiles recursively finds all the file paths in the given directory tree.
-// They are added to the given map as keys. Values will be safe function names
-// for each file, which will be used when generating the output code.
-func findFiles(fs http.FileSystem, toc *[]pathAsset) error {
-	walkFn := func(path string, fi os.FileInfo, err error) error {
-		if err != nil {
-			log.Printf("can't stat file %s: %v\n", path, err)
-			return nil
-		}
+// mountPath joins a path from inside an input tree with the prefix it's
+// mounted at.
+func mountPath(prefix, path string) string {
+	if prefix == "" || prefix == "/" {
+		return path
+	}
+	return pathpkg.Join(prefix, path)
+}
+
+// findFiles recursively finds all the file and directory paths across
+// every input tree, merging them (at their configured prefixes) into toc.
+// It returns an error if two inputs collide on the same mounted path.
+func findFiles(c *Config, toc *[]pathAsset) error {
+	for i := range c.Input {
+		ic := &c.Input[i]
 
-		switch {
-		case fi.IsDir():
-			entries, err := readDirPaths(fs, path)
+		walkFn := func(path string, fi os.FileInfo, err error) error {
 			if err != nil {
-				return err
+				log.Printf("can't stat file %s: %v\n", path, err)
+				return nil
+			}
+
+			if path != "/" && fi.IsDir() && !ic.Recursive {
+				return filepath.SkipDir
+			}
+			if path != "/" && !ic.includeAsset(pathpkg.Base(path)) {
+				if fi.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			mp := mountPath(ic.Prefix, path)
+
+			modTime := fi.ModTime()
+			if c.Reproducible {
+				modTime = c.SourceDateEpoch
+			}
+
+			switch {
+			case fi.IsDir():
+				entries, err := readDirPaths(ic, path)
+				if err != nil {
+					return err
+				}
+
+				*toc = append(*toc, pathAsset{
+					path:       mp,
+					sourceFS:   ic.FS,
+					sourcePath: path,
+					asset: &dir{
+						name:    pathpkg.Base(mp),
+						entries: entries,
+						modTime: modTime,
+					},
+				})
+
+			case !fi.IsDir():
+				var compressor Compressor
+				if c.CompressorFunc != nil {
+					compressor = c.CompressorFunc(mp, fi.Size())
+				}
+				if compressor == nil {
+					compressor = c.Compressor
+				}
+
+				var asset interface{}
+				if compressor != nil {
+					asset = &encodedFile{
+						name:             pathpkg.Base(path),
+						uncompressedSize: fi.Size(),
+						modTime:          modTime,
+						compressor:       compressor,
+					}
+				} else {
+					ratio, err := probeCompressRatio(ic.FS, path, fi.Size())
+					if err != nil {
+						return err
+					}
+
+					if ratio > c.MinCompressRatio {
+						asset = &rawFile{
+							name:          pathpkg.Base(path),
+							size:          fi.Size(),
+							modTime:       modTime,
+							compressRatio: ratio,
+						}
+					} else {
+						asset = &compressedFile{
+							name:             pathpkg.Base(path),
+							uncompressedSize: fi.Size(),
+							modTime:          modTime,
+							compressRatio:    ratio,
+						}
+					}
+				}
+
+				*toc = append(*toc, pathAsset{
+					path:       mp,
+					sourceFS:   ic.FS,
+					sourcePath: path,
+					asset:      asset,
+				})
 			}
 
-			*toc = append(*toc, pathAsset{
-				path: path,
-				asset: &dir{
-					name:    pathpkg.Base(path),
-					entries: entries,
-					modTime: fi.ModTime(),
-				},
-			})
-
-		case !fi.IsDir():
-			*toc = append(*toc, pathAsset{
-				path: path,
-				asset: &compressedFile{
-					name:             pathpkg.Base(path),
-					uncompressedSize: fi.Size(),
-					modTime:          fi.ModTime(),
-				},
-			})
+			return nil
 		}
 
-		return nil
+		err := vfsutil.Walk(ic.FS, "/", walkFn)
+		if err != nil {
+			return err
+		}
 	}
 
-	err := vfsutil.Walk(fs, "/", walkFn)
-	if err != nil {
-		return err
+	seen := make(map[string]bool, len(*toc))
+	for _, pa := range *toc {
+		if seen[pa.path] {
+			return fmt.Errorf("vfsgen: duplicate output path %q (two inputs produced the same mounted path)", pa.path)
+		}
+		seen[pa.path] = true
 	}
 
+	linkMounts(c, toc)
+
 	return nil
 }
 
+// linkMounts makes every non-root input mount appear as a child entry of
+// its parent directory, synthesizing intermediate directories where
+// needed. Without this, each input's dir.entries only lists its own
+// on-disk children, so a second input mounted at e.g. "/docs" is reachable
+// by direct Open/ReadFile but invisible to fs.ReadDir, fs.WalkDir and
+// http.FileServer's directory index at "/" - the merged tree would answer
+// lookups but never actually be one coherent, enumerable filesystem.
+func linkMounts(c *Config, toc *[]pathAsset) {
+	dirs := make(map[string]*dir, len(*toc))
+	for _, pa := range *toc {
+		if d, ok := pa.asset.(*dir); ok {
+			dirs[pa.path] = d
+		}
+	}
+
+	ensureDir := func(path string) *dir {
+		if d, ok := dirs[path]; ok {
+			return d
+		}
+		modTime := time.Time{}
+		if c.Reproducible {
+			modTime = c.SourceDateEpoch
+		}
+		d := &dir{name: pathpkg.Base(path), modTime: modTime}
+		*toc = append(*toc, pathAsset{path: path, asset: d})
+		dirs[path] = d
+		return d
+	}
+
+	linkEntry := func(d *dir, child string) {
+		for _, entry := range d.entries {
+			if entry == child {
+				return
+			}
+		}
+		d.entries = append(d.entries, child)
+		sort.Strings(d.entries)
+	}
+
+	for i := range c.Input {
+		child := c.Input[i].Prefix
+		for child != "/" {
+			parent := pathpkg.Dir(child)
+			linkEntry(ensureDir(parent), child)
+			child = parent
+		}
+	}
+}
+
 type pathAsset struct {
-	path  string
+	path string
+
+	// sourceFS and sourcePath locate the asset's content in the input
+	// tree it came from, which may differ from path once Prefix is set.
+	sourceFS   http.FileSystem
+	sourcePath string
+
 	asset interface{}
 }
 
 // writeAssets writes the code file.
-func writeAssets(w io.Writer, c *Config, toc []pathAsset) error {
+func writeAssets(w io.Writer, c *Config, toc []pathAsset, encodings map[string]bool) error {
 	_, err := fmt.Fprint(w, `import (
 	"bytes"
 	"compress/gzip"
@@ -156,12 +337,26 @@ func writeAssets(w io.Writer, c *Config, toc []pathAsset) error {
 	"net/http"
 	"os"
 	"time"
-)
-
 `)
 	if err != nil {
 		return err
 	}
+	if encodings["zstd"] {
+		_, err = fmt.Fprint(w, "\n\t\"github.com/klauspost/compress/zstd\"\n")
+		if err != nil {
+			return err
+		}
+	}
+	if encodings["br"] {
+		_, err = fmt.Fprint(w, "\n\t\"github.com/andybalholm/brotli\"\n")
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprint(w, ")\n\n")
+	if err != nil {
+		return err
+	}
 
 	_, err = fmt.Fprintf(w, `type assetsFS map[string]interface{}
 
@@ -187,15 +382,16 @@ var AssetsFS http.FileSystem = func() assetsFS {
 			fmt.Fprintf(w, "\t\t\tmodTime: mustUnmarshalTextTime(%q),\n", string(modTimeBytes))
 			fmt.Fprintf(w, "\t\t},\n")
 		case *compressedFile:
+			fmt.Fprintf(w, "\t\t// %s: gzip compressed (ratio %.3f < %.3f)\n", pathAsset.path, asset.compressRatio, c.MinCompressRatio)
 			_, err = fmt.Fprintf(w, "\t\t%q: &compressedFile{\n", pathAsset.path)
 			if err != nil {
 				return err
 			}
 			fmt.Fprintf(w, "\t\t\tname:              %q,\n", asset.name)
 			fmt.Fprintf(w, "\t\t\tcompressedContent: []byte(\"")
-			f, _ := c.Input.Open(pathAsset.path)
+			f, _ := pathAsset.sourceFS.Open(pathAsset.sourcePath)
 			sw := &StringWriter{Writer: w}
-			gz := gzip.NewWriter(sw)
+			gz := newGzipWriter(sw, c.Reproducible)
 			io.Copy(gz, f)
 			gz.Close()
 			f.Close()
@@ -207,6 +403,52 @@ var AssetsFS http.FileSystem = func() assetsFS {
 			}
 			fmt.Fprintf(w, "\t\t\tmodTime:           mustUnmarshalTextTime(%q),\n", string(modTimeBytes))
 			fmt.Fprintf(w, "\t\t},\n")
+		case *rawFile:
+			fmt.Fprintf(w, "\t\t// %s: stored raw, not gzip compressed (ratio %.3f >= %.3f)\n", pathAsset.path, asset.compressRatio, c.MinCompressRatio)
+			_, err = fmt.Fprintf(w, "\t\t%q: &rawFile{\n", pathAsset.path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t\tname:    %q,\n", asset.name)
+			fmt.Fprintf(w, "\t\t\tcontent: []byte(\"")
+			f, _ := pathAsset.sourceFS.Open(pathAsset.sourcePath)
+			sw := &StringWriter{Writer: w}
+			io.Copy(sw, f)
+			f.Close()
+			fmt.Fprintf(w, "\"),\n")
+			modTimeBytes, err := asset.modTime.MarshalText()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t\tmodTime: mustUnmarshalTextTime(%q),\n", string(modTimeBytes))
+			fmt.Fprintf(w, "\t\t},\n")
+		case *encodedFile:
+			ident, err := encodingIdent(asset.compressor.RuntimeDecoder())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t// %s: %s compressed (Config.Compressor)\n", pathAsset.path, asset.compressor.Extension())
+			_, err = fmt.Fprintf(w, "\t\t%q: &encodedFile{\n", pathAsset.path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t\tname:             %q,\n", asset.name)
+			fmt.Fprintf(w, "\t\t\tencoding:         encoding%s,\n", ident)
+			fmt.Fprintf(w, "\t\t\tcontent:          []byte(\"")
+			f, _ := pathAsset.sourceFS.Open(pathAsset.sourcePath)
+			sw := &StringWriter{Writer: w}
+			enc := asset.compressor.Encode(sw)
+			io.Copy(enc, f)
+			enc.Close()
+			f.Close()
+			fmt.Fprintf(w, "\"),\n")
+			fmt.Fprintf(w, "\t\t\tuncompressedSize: %d,\n", asset.uncompressedSize)
+			modTimeBytes, err := asset.modTime.MarshalText()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t\tmodTime:          mustUnmarshalTextTime(%q),\n", string(modTimeBytes))
+			fmt.Fprintf(w, "\t\t},\n")
 		}
 	}
 
@@ -234,29 +476,67 @@ var AssetsFS http.FileSystem = func() assetsFS {
 	return nil
 }
 
-func writeVFS(w io.Writer) error {
-	_, err := fmt.Fprint(w, `
+func writeVFS(w io.Writer, encodings map[string]bool) error {
+	_, err := io.WriteString(w, `
 func (fs assetsFS) Open(path string) (http.File, error) {
 	f, ok := fs[path]
 	if !ok {
 		return nil, os.ErrNotExist
 	}
 
-	if cf, ok := f.(*compressedFile); ok {
-		gr, err := gzip.NewReader(bytes.NewReader(cf.compressedContent))
+	switch f := f.(type) {
+	case *compressedFile:
+		gr, err := gzip.NewReader(bytes.NewReader(f.compressedContent))
 		if err != nil {
 			// This should never happen because we generate the gzip bytes such that they are always valid.
 			panic("unexpected error reading own gzip compressed bytes: " + err.Error())
 		}
 		return &compressedFileInstance{
-			compressedFile: cf,
+			compressedFile: f,
 			gr:             gr,
 		}, nil
+	case *rawFile:
+		return &rawFileInstance{
+			rawFile: f,
+			r:       bytes.NewReader(f.content),
+		}, nil`)
+	if err != nil {
+		return err
+	}
+	if len(encodings) > 0 {
+		_, err = io.WriteString(w, `
+	case *encodedFile:
+		content, err := decodeEncodedFile(f)
+		if err != nil {
+			// This should never happen because we generate the bytes for
+			// whichever encoding f.encoding names.
+			panic("unexpected error decoding encoded asset: " + err.Error())
+		}
+		return &encodedFileInstance{
+			encodedFile: f,
+			r:           bytes.NewReader(content),
+		}, nil`)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, `
 	}
 
 	return f.(http.File), nil
 }
+`)
+	if err != nil {
+		return err
+	}
+	if len(encodings) > 0 {
+		err = writeEncodedFileSupport(w, encodings)
+		if err != nil {
+			return err
+		}
+	}
 
+	_, err = io.WriteString(w, `
 func mustUnmarshalTextTime(text string) time.Time {
 	var t time.Time
 	err := t.UnmarshalText([]byte(text))
@@ -293,19 +573,116 @@ func (f *compressedFile) Sys() interface{}   { return nil }
 
 type compressedFileInstance struct {
 	*compressedFile
-	gr io.ReadCloser
+	gr io.ReadCloser // streaming decompressor; used until a Seek forces full decompression
+
+	pos  int64         // current read offset while streaming from gr
+	full *bytes.Reader // fully decompressed content, set once a backward Seek needs it
 }
 
 func (f *compressedFileInstance) Read(p []byte) (n int, err error) {
-	return f.gr.Read(p)
+	if f.full != nil {
+		return f.full.Read(p)
+	}
+	n, err = f.gr.Read(p)
+	f.pos += int64(n)
+	return n, err
 }
+
+// Seek implements io.Seeker, which http.ServeContent relies on to serve
+// Range requests and to sniff content types. Rather than always
+// decompressing the whole file up front, it special-cases the two seek
+// patterns ServeContent actually issues against a forward-only stream:
+// resetting to the start (cheap: a fresh gzip reader) and seeking
+// forward (cheap: discard the skipped bytes). Only a genuine backward
+// seek pays for full decompression.
 func (f *compressedFileInstance) Seek(offset int64, whence int) (int64, error) {
-	panic("Seek not yet implemented")
+	if f.full != nil {
+		return f.full.Seek(offset, whence)
+	}
+
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = f.pos + offset
+	case io.SeekEnd:
+		target = f.uncompressedSize + offset
+	default:
+		return 0, fmt.Errorf("compressedFileInstance.Seek: invalid whence %d", whence)
+	}
+
+	switch {
+	case target == 0:
+		gr, err := gzip.NewReader(bytes.NewReader(f.compressedContent))
+		if err != nil {
+			return 0, err
+		}
+		f.gr.Close()
+		f.gr = gr
+		f.pos = 0
+		return 0, nil
+
+	case target >= f.pos:
+		if _, err := io.CopyN(io.Discard, f.gr, target-f.pos); err != nil {
+			return 0, err
+		}
+		f.pos = target
+		return f.pos, nil
+
+	default:
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, f.gr); err != nil {
+			return 0, err
+		}
+		f.gr.Close()
+		f.full = bytes.NewReader(buf.Bytes())
+		return f.full.Seek(target, io.SeekStart)
+	}
 }
+
 func (f *compressedFileInstance) Close() error {
+	if f.full != nil {
+		return nil
+	}
 	return f.gr.Close()
 }
 
+// rawFile is a file asset whose content didn't compress well enough to be
+// worth gzipping, stored and served verbatim instead.
+type rawFile struct {
+	name    string
+	content []byte
+	modTime time.Time
+}
+
+func (f *rawFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, fmt.Errorf("cannot Readdir from file %s", f.name)
+}
+func (f *rawFile) Stat() (os.FileInfo, error) { return f, nil }
+
+func (f *rawFile) Name() string       { return f.name }
+func (f *rawFile) Size() int64        { return int64(len(f.content)) }
+func (f *rawFile) Mode() os.FileMode  { return 0444 }
+func (f *rawFile) ModTime() time.Time { return f.modTime }
+func (f *rawFile) IsDir() bool        { return false }
+func (f *rawFile) Sys() interface{}   { return nil }
+
+type rawFileInstance struct {
+	*rawFile
+	r *bytes.Reader
+}
+
+func (f *rawFileInstance) Read(p []byte) (n int, err error) {
+	return f.r.Read(p)
+}
+func (f *rawFileInstance) Seek(offset int64, whence int) (int64, error) {
+	return f.r.Seek(offset, whence)
+}
+func (f *rawFileInstance) Close() error {
+	return nil
+}
+
 // dir is ...
 type dir struct {
 	name    string
@@ -337,3 +714,120 @@ func (d *dir) Sys() interface{}   { return nil }
 `)
 	return err
 }
+
+// writeEncodedFileSupport writes the encoding tag, the encodedFile/
+// encodedFileInstance types, and the decode dispatch in openEncodedFile,
+// covering only the encodings actually present in encodings so the
+// generated file imports nothing it doesn't use.
+func writeEncodedFileSupport(w io.Writer, encodings map[string]bool) error {
+	_, err := io.WriteString(w, `
+// encoding identifies the Compressor an encodedFile's content was written
+// with, so Open knows how to decode it.
+type encoding uint8
+
+const (
+	encodingGzip encoding = iota
+	encodingZstd
+	encodingBrotli
+	encodingNone
+)
+
+// encodedFile is a file asset compressed by an explicit Config.Compressor
+// (or Config.CompressorFunc), rather than the default gzip-or-raw choice.
+type encodedFile struct {
+	name             string
+	encoding         encoding
+	content          []byte
+	uncompressedSize int64
+	modTime          time.Time
+}
+
+func (f *encodedFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, fmt.Errorf("cannot Readdir from file %s", f.name)
+}
+func (f *encodedFile) Stat() (os.FileInfo, error) { return f, nil }
+
+func (f *encodedFile) Name() string       { return f.name }
+func (f *encodedFile) Size() int64        { return f.uncompressedSize }
+func (f *encodedFile) Mode() os.FileMode  { return 0444 }
+func (f *encodedFile) ModTime() time.Time { return f.modTime }
+func (f *encodedFile) IsDir() bool        { return false }
+func (f *encodedFile) Sys() interface{}   { return nil }
+
+// encodedFileInstance decodes eagerly, rather than streaming like
+// compressedFileInstance: Compressor is a pluggable interface, so there's
+// no single decompression library whose reader we can special-case a
+// cheap Seek for. A bytes.Reader over the fully decoded content gives
+// Read and Seek for free.
+type encodedFileInstance struct {
+	*encodedFile
+	r *bytes.Reader
+}
+
+func (f *encodedFileInstance) Read(p []byte) (n int, err error) {
+	return f.r.Read(p)
+}
+func (f *encodedFileInstance) Seek(offset int64, whence int) (int64, error) {
+	return f.r.Seek(offset, whence)
+}
+func (f *encodedFileInstance) Close() error {
+	return nil
+}
+
+// decodeEncodedFile decodes f.content according to f.encoding.
+func decodeEncodedFile(f *encodedFile) ([]byte, error) {
+	switch f.encoding {`)
+	if err != nil {
+		return err
+	}
+
+	if encodings["gzip"] {
+		_, err = io.WriteString(w, `
+	case encodingGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(f.content))
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		return io.ReadAll(gr)`)
+		if err != nil {
+			return err
+		}
+	}
+	if encodings["zstd"] {
+		_, err = io.WriteString(w, `
+	case encodingZstd:
+		d, err := zstd.NewReader(bytes.NewReader(f.content))
+		if err != nil {
+			return nil, err
+		}
+		defer d.Close()
+		return io.ReadAll(d)`)
+		if err != nil {
+			return err
+		}
+	}
+	if encodings["br"] {
+		_, err = io.WriteString(w, `
+	case encodingBrotli:
+		return io.ReadAll(brotli.NewReader(bytes.NewReader(f.content)))`)
+		if err != nil {
+			return err
+		}
+	}
+	if encodings["none"] {
+		_, err = io.WriteString(w, `
+	case encodingNone:
+		return f.content, nil`)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, `
+	}
+	return nil, fmt.Errorf("encodedFile %s: unsupported encoding %d", f.name, f.encoding)
+}
+`)
+	return err
+}
@@ -0,0 +1,197 @@
+package vfsgen
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// devSourceEnvVar, when set, overrides the on-disk path recorded for a
+// single-input dev file. It has no effect when Config.Input has more
+// than one entry, since there would be no single path to override.
+const devSourceEnvVar = "VFSGEN_DEV_SOURCE"
+
+// writeDevFile generates c.DevOutput: a "dev" build-tagged companion to
+// the file Translate just wrote, which serves assets straight off disk
+// instead of from the embedded copy.
+func writeDevFile(c *Config) error {
+	mounts, err := devMounts(c)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(c.DevOutput)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buf := bufio.NewWriter(f)
+	defer buf.Flush()
+
+	_, err = fmt.Fprintf(buf, "// generated via `go generate`; do not edit\n\n// +build dev\n\npackage %s\n\n", c.Package)
+	if err != nil {
+		return err
+	}
+
+	if c.FSMode {
+		return writeDevFS(buf, mounts)
+	}
+	return writeDevHTTPFS(buf, mounts)
+}
+
+// devMount is an on-disk directory and the prefix it's mounted at,
+// mirroring one entry of Config.Input.
+type devMount struct {
+	prefix string
+	abs    string
+}
+
+// devMounts resolves the absolute, on-disk path of every InputConfig, so
+// the dev file can read straight from disk regardless of where `go
+// generate` happened to run.
+func devMounts(c *Config) ([]devMount, error) {
+	mounts := make([]devMount, 0, len(c.Input))
+	for i := range c.Input {
+		ic := &c.Input[i]
+
+		d, ok := ic.FS.(http.Dir)
+		if !ok {
+			return nil, fmt.Errorf("vfsgen: DevOutput requires InputConfig.FS to be http.Dir (the input mounted at %q is not)", ic.Prefix)
+		}
+
+		abs, err := filepath.Abs(string(d))
+		if err != nil {
+			return nil, err
+		}
+		if len(c.Input) == 1 {
+			if override := os.Getenv(devSourceEnvVar); override != "" {
+				abs = override
+			}
+		}
+
+		mounts = append(mounts, devMount{prefix: ic.Prefix, abs: abs})
+	}
+
+	// Both devFS.Open (http variant) and devTrimMount (fs.FS variant) take
+	// the first matching mount, so the most specific prefixes must come
+	// first in the generated literal or a root mount ("/") would shadow
+	// every other one regardless of Config.Input's declaration order.
+	sort.Slice(mounts, func(i, j int) bool { return len(mounts[i].prefix) > len(mounts[j].prefix) })
+
+	return mounts, nil
+}
+
+// writeDevHTTPFS writes an http.FileSystem-typed AssetsFS (matching the
+// type Translate emits in its default mode) that serves each mount
+// straight from disk.
+func writeDevHTTPFS(w *bufio.Writer, mounts []devMount) error {
+	_, err := fmt.Fprint(w, `import (
+	"net/http"
+	"os"
+	"strings"
+)
+
+// devFS multiplexes http.FileSystem mounts recorded at generation time,
+// mirroring the prefixes baked into the non-dev AssetsFS.
+type devFS []devMount
+
+type devMount struct {
+	prefix string
+	fs     http.FileSystem
+}
+
+func (d devFS) Open(name string) (http.File, error) {
+	for _, m := range d {
+		if m.prefix == "/" || name == m.prefix {
+			rel := strings.TrimPrefix(name, m.prefix)
+			if rel == "" {
+				rel = "/"
+			}
+			return m.fs.Open(rel)
+		}
+		if strings.HasPrefix(name, m.prefix+"/") {
+			return m.fs.Open(strings.TrimPrefix(name, m.prefix))
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+`)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprint(w, "var AssetsFS http.FileSystem = devFS{\n")
+	if err != nil {
+		return err
+	}
+	for _, m := range mounts {
+		fmt.Fprintf(w, "\t{prefix: %q, fs: http.Dir(%q)},\n", m.prefix, m.abs)
+	}
+	_, err = fmt.Fprint(w, "}\n")
+	return err
+}
+
+// writeDevFS writes an fs.FS-typed AssetsFS (matching the type Translate
+// emits in FSMode) that serves each mount straight from disk.
+func writeDevFS(w *bufio.Writer, mounts []devMount) error {
+	_, err := fmt.Fprint(w, `import (
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// devFS multiplexes fs.FS mounts recorded at generation time, mirroring
+// the prefixes baked into the non-dev AssetsFS.
+type devFS []devMount
+
+type devMount struct {
+	prefix string
+	fs     fs.FS
+}
+
+func (d devFS) Open(name string) (fs.File, error) {
+	for _, m := range d {
+		if rel, ok := devTrimMount(m.prefix, name); ok {
+			return m.fs.Open(rel)
+		}
+	}
+	return nil, fs.ErrNotExist
+}
+
+// devTrimMount reports whether name falls under prefix, and if so
+// returns it relative to prefix in fs.FS form (no leading slash, "."
+// for the mount root itself).
+func devTrimMount(prefix, name string) (string, bool) {
+	if prefix == "/" || prefix == "." {
+		return name, true
+	}
+	prefix = strings.TrimPrefix(prefix, "/")
+	if name == prefix {
+		return ".", true
+	}
+	if rel := strings.TrimPrefix(name, prefix+"/"); rel != name {
+		return rel, true
+	}
+	return "", false
+}
+
+`)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprint(w, "var AssetsFS fs.FS = devFS{\n")
+	if err != nil {
+		return err
+	}
+	for _, m := range mounts {
+		fmt.Fprintf(w, "\t{prefix: %q, fs: os.DirFS(%q)},\n", m.prefix, m.abs)
+	}
+	_, err = fmt.Fprint(w, "}\n")
+	return err
+}
@@ -0,0 +1,524 @@
+package vfsgen
+
+import (
+	"fmt"
+	"io"
+)
+
+// writeAssetsFS writes the code file for FSMode, using the same toc built
+// by findFiles but emitting an io/fs.FS-compatible assetsFS instead of an
+// http.FileSystem one.
+func writeAssetsFS(w io.Writer, c *Config, toc []pathAsset, encodings map[string]bool) error {
+	_, err := fmt.Fprint(w, `import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"time"
+`)
+	if err != nil {
+		return err
+	}
+	if encodings["zstd"] {
+		_, err = fmt.Fprint(w, "\n\t\"github.com/klauspost/compress/zstd\"\n")
+		if err != nil {
+			return err
+		}
+	}
+	if encodings["br"] {
+		_, err = fmt.Fprint(w, "\n\t\"github.com/andybalholm/brotli\"\n")
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprint(w, ")\n\n")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, `type assetsFS map[string]interface{}
+
+var AssetsFS fs.FS = func() assetsFS {
+	assetsFS := assetsFS{
+`)
+	if err != nil {
+		return err
+	}
+
+	for _, pathAsset := range toc {
+		switch asset := pathAsset.asset.(type) {
+		case *dir:
+			_, err = fmt.Fprintf(w, "\t\t%q: &dir{\n", pathAsset.path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t\tname:    %q,\n", asset.name)
+			modTimeBytes, err := asset.modTime.MarshalText()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t\tmodTime: mustUnmarshalTextTime(%q),\n", string(modTimeBytes))
+			fmt.Fprintf(w, "\t\t},\n")
+		case *compressedFile:
+			fmt.Fprintf(w, "\t\t// %s: gzip compressed (ratio %.3f < %.3f)\n", pathAsset.path, asset.compressRatio, c.MinCompressRatio)
+			_, err = fmt.Fprintf(w, "\t\t%q: &compressedFile{\n", pathAsset.path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t\tname:              %q,\n", asset.name)
+			fmt.Fprintf(w, "\t\t\tcompressedContent: []byte(\"")
+			f, _ := pathAsset.sourceFS.Open(pathAsset.sourcePath)
+			sw := &StringWriter{Writer: w}
+			gz := newGzipWriter(sw, c.Reproducible)
+			io.Copy(gz, f)
+			gz.Close()
+			f.Close()
+			fmt.Fprintf(w, "\"),\n")
+			fmt.Fprintf(w, "\t\t\tuncompressedSize:  %d,\n", asset.uncompressedSize)
+			modTimeBytes, err := asset.modTime.MarshalText()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t\tmodTime:           mustUnmarshalTextTime(%q),\n", string(modTimeBytes))
+			fmt.Fprintf(w, "\t\t},\n")
+		case *rawFile:
+			fmt.Fprintf(w, "\t\t// %s: stored raw, not gzip compressed (ratio %.3f >= %.3f)\n", pathAsset.path, asset.compressRatio, c.MinCompressRatio)
+			_, err = fmt.Fprintf(w, "\t\t%q: &rawFile{\n", pathAsset.path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t\tname:    %q,\n", asset.name)
+			fmt.Fprintf(w, "\t\t\tcontent: []byte(\"")
+			f, _ := pathAsset.sourceFS.Open(pathAsset.sourcePath)
+			sw := &StringWriter{Writer: w}
+			io.Copy(sw, f)
+			f.Close()
+			fmt.Fprintf(w, "\"),\n")
+			modTimeBytes, err := asset.modTime.MarshalText()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t\tmodTime: mustUnmarshalTextTime(%q),\n", string(modTimeBytes))
+			fmt.Fprintf(w, "\t\t},\n")
+		case *encodedFile:
+			ident, err := encodingIdent(asset.compressor.RuntimeDecoder())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t// %s: %s compressed (Config.Compressor)\n", pathAsset.path, asset.compressor.Extension())
+			_, err = fmt.Fprintf(w, "\t\t%q: &encodedFile{\n", pathAsset.path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t\tname:             %q,\n", asset.name)
+			fmt.Fprintf(w, "\t\t\tencoding:         encoding%s,\n", ident)
+			fmt.Fprintf(w, "\t\t\tcontent:          []byte(\"")
+			f, _ := pathAsset.sourceFS.Open(pathAsset.sourcePath)
+			sw := &StringWriter{Writer: w}
+			enc := asset.compressor.Encode(sw)
+			io.Copy(enc, f)
+			enc.Close()
+			f.Close()
+			fmt.Fprintf(w, "\"),\n")
+			fmt.Fprintf(w, "\t\t\tuncompressedSize: %d,\n", asset.uncompressedSize)
+			modTimeBytes, err := asset.modTime.MarshalText()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\t\tmodTime:          mustUnmarshalTextTime(%q),\n", string(modTimeBytes))
+			fmt.Fprintf(w, "\t\t},\n")
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "\t}\n\n")
+	if err != nil {
+		return err
+	}
+
+	for _, pathAsset := range toc {
+		switch asset := pathAsset.asset.(type) {
+		case *dir:
+			fmt.Fprintf(w, "\tassetsFS[%q].(*dir).entries = []fs.DirEntry{\n", pathAsset.path)
+			for _, entry := range asset.entries {
+				fmt.Fprintf(w, "\t\tassetsFS[%q].(fs.DirEntry),\n", entry)
+			}
+			fmt.Fprintf(w, "\t}\n")
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "\n\treturn assetsFS\n}()\n")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// writeVFSFS writes the fixed (non-asset-specific) part of an FSMode
+// generated file: the assetsFS method set implementing fs.FS,
+// fs.ReadDirFS, fs.ReadFileFS, fs.StatFS, fs.SubFS and fs.GlobFS, and
+// the compressedFile/dir asset types.
+func writeVFSFS(w io.Writer, encodings map[string]bool) error {
+	_, err := io.WriteString(w, `
+// toAssetsPath converts a name in fs.FS form ("." for the root, slash
+// separated and without a leading slash otherwise) to the form used as
+// keys in assetsFS.
+func toAssetsPath(name string) string {
+	if name == "." {
+		return "/"
+	}
+	return "/" + name
+}
+
+func (fsys assetsFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	f, ok := fsys[toAssetsPath(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	switch f := f.(type) {
+	case *compressedFile:
+		gr, err := gzip.NewReader(bytes.NewReader(f.compressedContent))
+		if err != nil {
+			// This should never happen because we generate the gzip bytes such that they are always valid.
+			panic("unexpected error reading own gzip compressed bytes: " + err.Error())
+		}
+		return &compressedFileInstance{
+			compressedFile: f,
+			gr:             gr,
+		}, nil
+	case *rawFile:
+		return &rawFileInstance{
+			rawFile: f,
+			r:       bytes.NewReader(f.content),
+		}, nil`)
+	if err != nil {
+		return err
+	}
+	if len(encodings) > 0 {
+		_, err = io.WriteString(w, `
+	case *encodedFile:
+		r, err := openEncodedFile(f)
+		if err != nil {
+			// This should never happen because we generate the bytes for
+			// whichever encoding f.encoding names.
+			panic("unexpected error decoding encoded asset: " + err.Error())
+		}
+		return &encodedFileInstance{
+			encodedFile: f,
+			r:           r,
+		}, nil`)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, `
+	}
+
+	return f.(fs.File), nil
+}
+
+func (fsys assetsFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	d, ok := f.(*dir)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fmt.Errorf("not a directory")}
+	}
+	return d.entries, nil
+}
+
+func (fsys assetsFS) ReadFile(name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+func (fsys assetsFS) Stat(name string) (fs.FileInfo, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return f.Stat()
+}
+
+func (fsys assetsFS) Sub(dir string) (fs.FS, error) {
+	if dir == "." {
+		return fsys, nil
+	}
+	if !fs.ValidPath(dir) {
+		return nil, &fs.PathError{Op: "sub", Path: dir, Err: fs.ErrInvalid}
+	}
+	if fi, err := fsys.Stat(dir); err != nil || !fi.IsDir() {
+		return nil, &fs.PathError{Op: "sub", Path: dir, Err: fs.ErrNotExist}
+	}
+	return &subFS{fsys: fsys, prefix: dir}, nil
+}
+
+func (fsys assetsFS) Glob(pattern string) ([]string, error) {
+	// fs.Glob prefers a GlobFS implementation over its default walk, which
+	// would otherwise recurse back into this method; hide the interface
+	// behind a plain fs.FS so it falls through to the default behavior.
+	return fs.Glob(struct{ fs.FS }{fsys}, pattern)
+}
+
+// subFS implements fs.FS for the subtree of an assetsFS rooted at prefix.
+type subFS struct {
+	fsys   assetsFS
+	prefix string
+}
+
+func (s *subFS) full(name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", fs.ErrInvalid
+	}
+	if name == "." {
+		return s.prefix, nil
+	}
+	return s.prefix + "/" + name, nil
+}
+
+func (s *subFS) Open(name string) (fs.File, error) {
+	full, err := s.full(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return s.fsys.Open(full)
+}
+`)
+	if err != nil {
+		return err
+	}
+	if len(encodings) > 0 {
+		err = writeEncodedFileSupportFS(w, encodings)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, `
+func mustUnmarshalTextTime(text string) time.Time {
+	var t time.Time
+	err := t.UnmarshalText([]byte(text))
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// compressedFile is ...
+type compressedFile struct {
+	name              string
+	compressedContent []byte
+	uncompressedSize  int64
+	modTime           time.Time
+}
+
+func (f *compressedFile) Stat() (fs.FileInfo, error) { return f, nil }
+
+func (f *compressedFile) GzipBytes() []byte {
+	log.Println("using GzipBytes for", f.name)
+	return f.compressedContent
+}
+
+func (f *compressedFile) Name() string               { return f.name }
+func (f *compressedFile) Size() int64                { return f.uncompressedSize }
+func (f *compressedFile) Mode() fs.FileMode          { return 0444 }
+func (f *compressedFile) ModTime() time.Time         { return f.modTime }
+func (f *compressedFile) IsDir() bool                { return false }
+func (f *compressedFile) Sys() interface{}           { return nil }
+func (f *compressedFile) Type() fs.FileMode          { return f.Mode().Type() }
+func (f *compressedFile) Info() (fs.FileInfo, error) { return f, nil }
+
+type compressedFileInstance struct {
+	*compressedFile
+	gr io.ReadCloser
+}
+
+func (f *compressedFileInstance) Read(p []byte) (n int, err error) {
+	return f.gr.Read(p)
+}
+func (f *compressedFileInstance) Close() error {
+	return f.gr.Close()
+}
+
+// rawFile is a file asset whose content didn't compress well enough to be
+// worth gzipping, stored and served verbatim instead.
+type rawFile struct {
+	name    string
+	content []byte
+	modTime time.Time
+}
+
+func (f *rawFile) Stat() (fs.FileInfo, error) { return f, nil }
+
+func (f *rawFile) Name() string               { return f.name }
+func (f *rawFile) Size() int64                { return int64(len(f.content)) }
+func (f *rawFile) Mode() fs.FileMode          { return 0444 }
+func (f *rawFile) ModTime() time.Time         { return f.modTime }
+func (f *rawFile) IsDir() bool                { return false }
+func (f *rawFile) Sys() interface{}           { return nil }
+func (f *rawFile) Type() fs.FileMode          { return f.Mode().Type() }
+func (f *rawFile) Info() (fs.FileInfo, error) { return f, nil }
+
+type rawFileInstance struct {
+	*rawFile
+	r *bytes.Reader
+}
+
+func (f *rawFileInstance) Read(p []byte) (n int, err error) {
+	return f.r.Read(p)
+}
+func (f *rawFileInstance) Close() error {
+	return nil
+}
+
+// dir is ...
+type dir struct {
+	name    string
+	entries []fs.DirEntry
+	modTime time.Time
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return d, nil }
+func (d *dir) Read([]byte) (int, error) {
+	return 0, fmt.Errorf("cannot Read from directory %s", d.name)
+}
+func (d *dir) Close() error { return nil }
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n > 0 && n < len(d.entries) {
+		return nil, fmt.Errorf("partial ReadDir not supported")
+	}
+	return d.entries, nil
+}
+
+func (d *dir) Name() string               { return d.name }
+func (d *dir) Size() int64                { return 0 }
+func (d *dir) Mode() fs.FileMode          { return 0755 | fs.ModeDir }
+func (d *dir) ModTime() time.Time         { return d.modTime }
+func (d *dir) IsDir() bool                { return true }
+func (d *dir) Sys() interface{}           { return nil }
+func (d *dir) Type() fs.FileMode          { return d.Mode().Type() }
+func (d *dir) Info() (fs.FileInfo, error) { return d, nil }
+`)
+	return err
+}
+
+// writeEncodedFileSupportFS writes the FSMode-flavored encoding tag, the
+// encodedFile/encodedFileInstance types implementing fs.File/fs.DirEntry,
+// and the decode dispatch in openEncodedFile, covering only the
+// encodings actually present in encodings so the generated file imports
+// nothing it doesn't use.
+func writeEncodedFileSupportFS(w io.Writer, encodings map[string]bool) error {
+	_, err := io.WriteString(w, `
+// encoding identifies the Compressor an encodedFile's content was written
+// with, so Open knows how to decode it.
+type encoding uint8
+
+const (
+	encodingGzip encoding = iota
+	encodingZstd
+	encodingBrotli
+	encodingNone
+)
+
+// encodedFile is a file asset compressed by an explicit Config.Compressor
+// (or Config.CompressorFunc), rather than the default gzip-or-raw choice.
+type encodedFile struct {
+	name             string
+	encoding         encoding
+	content          []byte
+	uncompressedSize int64
+	modTime          time.Time
+}
+
+func (f *encodedFile) Stat() (fs.FileInfo, error) { return f, nil }
+
+func (f *encodedFile) Name() string               { return f.name }
+func (f *encodedFile) Size() int64                { return f.uncompressedSize }
+func (f *encodedFile) Mode() fs.FileMode          { return 0444 }
+func (f *encodedFile) ModTime() time.Time         { return f.modTime }
+func (f *encodedFile) IsDir() bool                { return false }
+func (f *encodedFile) Sys() interface{}           { return nil }
+func (f *encodedFile) Type() fs.FileMode          { return f.Mode().Type() }
+func (f *encodedFile) Info() (fs.FileInfo, error) { return f, nil }
+
+type encodedFileInstance struct {
+	*encodedFile
+	r io.ReadCloser
+}
+
+func (f *encodedFileInstance) Read(p []byte) (n int, err error) {
+	return f.r.Read(p)
+}
+func (f *encodedFileInstance) Close() error {
+	return f.r.Close()
+}
+
+// openEncodedFile decodes f.content according to f.encoding.
+func openEncodedFile(f *encodedFile) (io.ReadCloser, error) {
+	switch f.encoding {`)
+	if err != nil {
+		return err
+	}
+
+	if encodings["gzip"] {
+		_, err = io.WriteString(w, `
+	case encodingGzip:
+		return gzip.NewReader(bytes.NewReader(f.content))`)
+		if err != nil {
+			return err
+		}
+	}
+	if encodings["zstd"] {
+		_, err = io.WriteString(w, `
+	case encodingZstd:
+		d, err := zstd.NewReader(bytes.NewReader(f.content))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil`)
+		if err != nil {
+			return err
+		}
+	}
+	if encodings["br"] {
+		_, err = io.WriteString(w, `
+	case encodingBrotli:
+		return io.NopCloser(brotli.NewReader(bytes.NewReader(f.content))), nil`)
+		if err != nil {
+			return err
+		}
+	}
+	if encodings["none"] {
+		_, err = io.WriteString(w, `
+	case encodingNone:
+		return io.NopCloser(bytes.NewReader(f.content)), nil`)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, `
+	}
+	return nil, fmt.Errorf("encodedFile %s: unsupported encoding %d", f.name, f.encoding)
+}
+`)
+	return err
+}
@@ -0,0 +1,782 @@
+package vfsgen_test
+
+import (
+	"crypto/rand"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mfridman/vfsgen"
+)
+
+// TestCompressedFileInstanceSeek_ServeContent generates a virtual
+// filesystem, then runs a small driver program (in its own process, so
+// it can import the generated package) that serves an embedded file
+// through http.ServeContent with a Range header. This exercises
+// compressedFileInstance.Seek, which http.ServeContent depends on for
+// both content-type sniffing and partial (206) responses.
+func TestCompressedFileInstanceSeek_ServeContent(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	dir := t.TempDir()
+	content := strings.Repeat("vfsgen seek test content, byte for byte. ", 200)
+	if err := os.WriteFile(filepath.Join(dir, "body.txt"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	outPath := filepath.Join(dir, "assets_vfsdata.go")
+	err := vfsgen.Translate(&vfsgen.Config{
+		Input:   []vfsgen.InputConfig{{FS: http.Dir(dir), Recursive: true}},
+		Output:  outPath,
+		Package: "main",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	driverPath := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(driverPath, []byte(serveContentDriverSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("go", "run", outPath, driverPath).CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run failed: %v\n%s", err, out)
+	}
+
+	got := string(out)
+	if !strings.Contains(got, "206") {
+		t.Errorf("expected a 206 Partial Content status, got:\n%s", got)
+	}
+	if want := content[10:20]; !strings.Contains(got, want) {
+		t.Errorf("expected ranged body to contain %q, got:\n%s", want, got)
+	}
+}
+
+const serveContentDriverSrc = `package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+func main() {
+	f, err := AssetsFS.Open("/body.txt")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		panic(err)
+	}
+
+	req := httptest.NewRequest("GET", "/body.txt", nil)
+	req.Header.Set("Range", "bytes=10-19")
+	rec := httptest.NewRecorder()
+	http.ServeContent(rec, req, fi.Name(), fi.ModTime(), f)
+
+	fmt.Println(rec.Code)
+	fmt.Println(rec.Body.String())
+}
+`
+
+// TestCompressorFunc generates a virtual filesystem with a per-file
+// Compressor selection, then runs a driver program that reads each file
+// back through AssetsFS, exercising every built-in Compressor's Encode
+// and the generated file's matching decode case.
+func TestCompressorFunc(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"gzip.txt":   "gzip content " + strings.Repeat("a", 100),
+		"zstd.txt":   "zstd content " + strings.Repeat("b", 100),
+		"brotli.txt": "brotli content " + strings.Repeat("c", 100),
+		"none.txt":   "none content",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outPath := filepath.Join(dir, "assets_vfsdata.go")
+	err := vfsgen.Translate(&vfsgen.Config{
+		Input:   []vfsgen.InputConfig{{FS: http.Dir(dir), Recursive: true}},
+		Output:  outPath,
+		Package: "main",
+		CompressorFunc: func(path string, size int64) vfsgen.Compressor {
+			switch {
+			case strings.Contains(path, "zstd"):
+				return vfsgen.ZstdCompressor{}
+			case strings.Contains(path, "brotli"):
+				return vfsgen.BrotliCompressor{}
+			case strings.Contains(path, "none"):
+				return vfsgen.NoCompression{}
+			default:
+				return vfsgen.GzipCompressor{}
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	driverPath := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(driverPath, []byte(compressorFuncDriverSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("go", "run", outPath, driverPath).CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run failed: %v\n%s", err, out)
+	}
+
+	got := string(out)
+	for name, content := range files {
+		if !strings.Contains(got, content) {
+			t.Errorf("expected decoded output to contain %s's content %q, got:\n%s", name, content, got)
+		}
+	}
+}
+
+const compressorFuncDriverSrc = `package main
+
+import (
+	"fmt"
+	"io"
+)
+
+func main() {
+	for _, name := range []string{"/gzip.txt", "/zstd.txt", "/brotli.txt", "/none.txt"} {
+		f, err := AssetsFS.Open(name)
+		if err != nil {
+			panic(err)
+		}
+		b, err := io.ReadAll(f)
+		if err != nil {
+			panic(err)
+		}
+		f.Close()
+		fmt.Println(string(b))
+	}
+}
+`
+
+// TestReproducible checks that Config.Reproducible makes two independent
+// generation runs over the same input byte-for-byte identical, and that
+// the output doesn't depend on the host filesystem's directory order.
+func TestReproducible(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "zebra.txt"), []byte("zebra content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "alpha.txt"), []byte("alpha content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	generate := func(outPath string) []byte {
+		t.Helper()
+		err := vfsgen.Translate(&vfsgen.Config{
+			Input:        []vfsgen.InputConfig{{FS: http.Dir(dir)}},
+			Output:       outPath,
+			Package:      "main",
+			Reproducible: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		src, err := os.ReadFile(outPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return src
+	}
+
+	first := generate(filepath.Join(t.TempDir(), "out.go"))
+	second := generate(filepath.Join(t.TempDir(), "out.go"))
+	if string(first) != string(second) {
+		t.Error("expected two Reproducible generations of the same input to be byte-identical")
+	}
+
+	// The table of contents must list paths in sorted order, not whatever
+	// order the host filesystem happened to return directory entries in.
+	alphaIdx := strings.Index(string(first), `"/alpha.txt"`)
+	zebraIdx := strings.Index(string(first), `"/zebra.txt"`)
+	if alphaIdx == -1 || zebraIdx == -1 {
+		t.Fatal("generated source is missing an expected entry")
+	}
+	if alphaIdx > zebraIdx {
+		t.Error("expected /alpha.txt to precede /zebra.txt in the sorted table of contents")
+	}
+
+	if strings.Contains(string(first), dir) {
+		t.Error("expected no trace of the absolute source path in reproducible output")
+	}
+}
+
+// TestCompressRatioProbe checks that findFiles' raw-vs-gzip choice tracks
+// probeCompressRatio: a file that doesn't shrink under gzip (random bytes)
+// is stored as a rawFile, while one that does (repetitive text) is stored
+// as a compressedFile.
+func TestCompressRatioProbe(t *testing.T) {
+	dir := t.TempDir()
+
+	random := make([]byte, 8000)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "random.bin"), random, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	text := []byte(strings.Repeat("hello world ", 500))
+	if err := os.WriteFile(filepath.Join(dir, "text.txt"), text, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	outPath := filepath.Join(dir, "assets_vfsdata.go")
+	err := vfsgen.Translate(&vfsgen.Config{
+		Input:   []vfsgen.InputConfig{{FS: http.Dir(dir)}},
+		Output:  outPath,
+		Package: "main",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	randomIdx := strings.Index(string(src), `"/random.bin": &`)
+	if randomIdx == -1 {
+		t.Fatal("generated source has no entry for /random.bin")
+	}
+	if !strings.HasPrefix(string(src[randomIdx+len(`"/random.bin": &`):]), "rawFile{") {
+		t.Errorf("expected /random.bin to be stored as a rawFile, got:\n%s", src[randomIdx:randomIdx+60])
+	}
+
+	textIdx := strings.Index(string(src), `"/text.txt": &`)
+	if textIdx == -1 {
+		t.Fatal("generated source has no entry for /text.txt")
+	}
+	if !strings.HasPrefix(string(src[textIdx+len(`"/text.txt": &`):]), "compressedFile{") {
+		t.Errorf("expected /text.txt to be stored as a compressedFile, got:\n%s", src[textIdx:textIdx+60])
+	}
+}
+
+// TestMultiInputMerge generates a virtual filesystem from two input trees
+// mounted at different prefixes and checks that both are reachable in the
+// merged result.
+func TestMultiInputMerge(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	srcDir := t.TempDir()
+	rootDir := filepath.Join(srcDir, "root")
+	docsDir := filepath.Join(srcDir, "docs")
+	if err := os.MkdirAll(rootDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(docsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rootDir, "index.html"), []byte("root index"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(docsDir, "readme.md"), []byte("docs readme"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	outPath := filepath.Join(dir, "assets_vfsdata.go")
+	err := vfsgen.Translate(&vfsgen.Config{
+		Input: []vfsgen.InputConfig{
+			{FS: http.Dir(rootDir), Prefix: "/"},
+			{FS: http.Dir(docsDir), Prefix: "/docs"},
+		},
+		Output:  outPath,
+		Package: "main",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	driverPath := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(driverPath, []byte(multiInputDriverSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("go", "run", outPath, driverPath).CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run failed: %v\n%s", err, out)
+	}
+
+	got := string(out)
+	if !strings.Contains(got, "root index") {
+		t.Errorf("expected /index.html to contain root content, got:\n%s", got)
+	}
+	if !strings.Contains(got, "docs readme") {
+		t.Errorf("expected /docs/readme.md to contain docs content, got:\n%s", got)
+	}
+}
+
+const multiInputDriverSrc = `package main
+
+import (
+	"fmt"
+	"io"
+)
+
+func main() {
+	for _, name := range []string{"/index.html", "/docs/readme.md"} {
+		f, err := AssetsFS.Open(name)
+		if err != nil {
+			panic(err)
+		}
+		b, err := io.ReadAll(f)
+		if err != nil {
+			panic(err)
+		}
+		f.Close()
+		fmt.Println(string(b))
+	}
+}
+`
+
+// TestMultiInputMountIsEnumerable checks that a second input mounted at a
+// non-root prefix shows up as a child entry of its parent directory, not
+// just as a directly Open-able path. Both fs.ReadDir/fs.WalkDir (FSMode)
+// and http.File's Readdir (default mode) must see it, since a merged
+// input tree needs to be one coherent, enumerable filesystem for things
+// like http.FileServer's directory index and fs.WalkDir to work.
+func TestMultiInputMountIsEnumerable(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	srcDir := t.TempDir()
+	rootDir := filepath.Join(srcDir, "root")
+	docsDir := filepath.Join(srcDir, "docs")
+	if err := os.MkdirAll(rootDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(docsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rootDir, "index.html"), []byte("root index"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(docsDir, "readme.md"), []byte("docs readme"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	input := []vfsgen.InputConfig{
+		{FS: http.Dir(rootDir), Prefix: "/"},
+		{FS: http.Dir(docsDir), Prefix: "/docs"},
+	}
+
+	t.Run("FSMode", func(t *testing.T) {
+		dir := t.TempDir()
+		outPath := filepath.Join(dir, "assets_vfsdata.go")
+		err := vfsgen.Translate(&vfsgen.Config{
+			Input:   input,
+			Output:  outPath,
+			Package: "main",
+			FSMode:  true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		driverPath := filepath.Join(dir, "main.go")
+		if err := os.WriteFile(driverPath, []byte(enumerableMountDriverSrcFS), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := exec.Command("go", "run", outPath, driverPath).CombinedOutput()
+		if err != nil {
+			t.Fatalf("go run failed: %v\n%s", err, out)
+		}
+		got := string(out)
+		if !strings.Contains(got, "readdir: docs") {
+			t.Errorf("expected fs.ReadDir(AssetsFS, \".\") to list docs, got:\n%s", got)
+		}
+		if !strings.Contains(got, "walk: docs/readme.md") {
+			t.Errorf("expected fs.WalkDir to descend into docs/readme.md, got:\n%s", got)
+		}
+	})
+
+	t.Run("http.FileSystem", func(t *testing.T) {
+		dir := t.TempDir()
+		outPath := filepath.Join(dir, "assets_vfsdata.go")
+		err := vfsgen.Translate(&vfsgen.Config{
+			Input:   input,
+			Output:  outPath,
+			Package: "main",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		driverPath := filepath.Join(dir, "main.go")
+		if err := os.WriteFile(driverPath, []byte(enumerableMountDriverSrcHTTP), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := exec.Command("go", "run", outPath, driverPath).CombinedOutput()
+		if err != nil {
+			t.Fatalf("go run failed: %v\n%s", err, out)
+		}
+		if got := string(out); !strings.Contains(got, "readdir: docs") {
+			t.Errorf("expected the root's Readdir to list docs, got:\n%s", got)
+		}
+	})
+}
+
+const enumerableMountDriverSrcFS = `package main
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+func main() {
+	entries, err := fs.ReadDir(AssetsFS, ".")
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range entries {
+		fmt.Println("readdir:", e.Name())
+	}
+
+	err = fs.WalkDir(AssetsFS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		fmt.Println("walk:", path)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+`
+
+const enumerableMountDriverSrcHTTP = `package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	f, err := AssetsFS.Open("/")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	fis, err := f.Readdir(0)
+	if err != nil {
+		panic(err)
+	}
+	for _, fi := range fis {
+		fmt.Println("readdir:", fi.Name())
+	}
+}
+`
+
+// TestValidateRejectsPrefixCollision checks that two InputConfig entries
+// mounted at the same prefix are rejected before any file is touched.
+func TestValidateRejectsPrefixCollision(t *testing.T) {
+	dir := t.TempDir()
+
+	err := vfsgen.Translate(&vfsgen.Config{
+		Input: []vfsgen.InputConfig{
+			{FS: http.Dir(dir), Prefix: "/docs"},
+			{FS: http.Dir(dir), Prefix: "/docs"},
+		},
+		Output:  filepath.Join(dir, "assets_vfsdata.go"),
+		Package: "main",
+	})
+	if err == nil {
+		t.Fatal("expected an error for colliding input prefixes, got nil")
+	}
+	if !strings.Contains(err.Error(), "prefix") {
+		t.Errorf("expected the error to mention the prefix collision, got: %v", err)
+	}
+}
+
+// TestFindFilesRejectsDuplicateOutputPath checks that two inputs whose
+// prefixes differ, but whose merged paths collide, are rejected by
+// findFiles, since the generated virtual filesystem can't hold two assets
+// at the same path. One input is mounted at "/", already containing a
+// "/docs/same.txt"; the other is mounted at "/docs" and independently
+// produces the same mounted path.
+func TestFindFilesRejectsDuplicateOutputPath(t *testing.T) {
+	srcDir := t.TempDir()
+	rootDir := filepath.Join(srcDir, "root")
+	docsDir := filepath.Join(srcDir, "docs")
+	if err := os.MkdirAll(filepath.Join(rootDir, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(docsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rootDir, "docs", "same.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(docsDir, "same.txt"), []byte("b"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := vfsgen.Translate(&vfsgen.Config{
+		Input: []vfsgen.InputConfig{
+			{FS: http.Dir(rootDir), Prefix: "/", Recursive: true},
+			{FS: http.Dir(docsDir), Prefix: "/docs"},
+		},
+		Output:  filepath.Join(srcDir, "assets_vfsdata.go"),
+		Package: "main",
+	})
+	if err == nil {
+		t.Fatal("expected an error for a duplicate merged output path, got nil")
+	}
+	if !strings.Contains(err.Error(), "duplicate output path") {
+		t.Errorf("expected the error to mention the duplicate output path, got: %v", err)
+	}
+}
+
+// TestFSMode generates an io/fs.FS-based virtual filesystem and runs a
+// driver that exercises every interface it's meant to implement beyond
+// plain fs.FS: ReadDirFS, ReadFileFS, StatFS, SubFS and GlobFS.
+func TestFSMode(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "top.txt"), []byte("top content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "nested.txt"), []byte("nested content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	outPath := filepath.Join(dir, "assets_vfsdata.go")
+	err := vfsgen.Translate(&vfsgen.Config{
+		Input:   []vfsgen.InputConfig{{FS: http.Dir(dir), Recursive: true}},
+		Output:  outPath,
+		Package: "main",
+		FSMode:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	driverPath := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(driverPath, []byte(fsModeDriverSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("go", "run", outPath, driverPath).CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run failed: %v\n%s", err, out)
+	}
+
+	got := string(out)
+	for _, want := range []string{"ReadFile: top content", "ReadDir: nested.txt", "Stat: top.txt", "Sub+ReadFile: nested content", "Glob: sub/nested.txt"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+const fsModeDriverSrc = `package main
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+func main() {
+	b, err := fs.ReadFile(AssetsFS, "top.txt")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("ReadFile:", string(b))
+
+	entries, err := fs.ReadDir(AssetsFS, "sub")
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range entries {
+		fmt.Println("ReadDir:", e.Name())
+	}
+
+	fi, err := fs.Stat(AssetsFS, "top.txt")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("Stat:", fi.Name())
+
+	sub, err := fs.Sub(AssetsFS, "sub")
+	if err != nil {
+		panic(err)
+	}
+	b, err = fs.ReadFile(sub, "nested.txt")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("Sub+ReadFile:", string(b))
+
+	matches, err := fs.Glob(AssetsFS, "sub/*.txt")
+	if err != nil {
+		panic(err)
+	}
+	for _, m := range matches {
+		fmt.Println("Glob:", m)
+	}
+}
+`
+
+// TestDevModeRootMountDoesNotShadowMoreSpecificMount generates a dev-mode
+// companion file for a config with a root mount ("/") declared before a
+// more specific one ("/docs"), which is how multi-input configs are meant
+// to be written. A naive first-match-wins dev file would resolve
+// "/docs/readme.md" against the root mount instead, since "/" matches
+// every name. Exercises both the http.FileSystem and fs.FS dev variants.
+func TestDevModeRootMountDoesNotShadowMoreSpecificMount(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	srcDir := t.TempDir()
+	rootDir := filepath.Join(srcDir, "root")
+	docsDir := filepath.Join(srcDir, "docs")
+	if err := os.MkdirAll(rootDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(docsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rootDir, "index.html"), []byte("root index"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(docsDir, "readme.md"), []byte("docs readme"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	input := []vfsgen.InputConfig{
+		{FS: http.Dir(rootDir), Prefix: "/"},
+		{FS: http.Dir(docsDir), Prefix: "/docs"},
+	}
+
+	t.Run("http.FileSystem", func(t *testing.T) {
+		dir := t.TempDir()
+		outPath := filepath.Join(dir, "assets_vfsdata.go")
+		devPath := filepath.Join(dir, "assets_vfsdata_dev.go")
+		err := vfsgen.Translate(&vfsgen.Config{
+			Input:     input,
+			Output:    outPath,
+			DevOutput: devPath,
+			Package:   "main",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		driverPath := filepath.Join(dir, "main.go")
+		if err := os.WriteFile(driverPath, []byte(devMountDriverSrc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := exec.Command("go", "run", "-tags", "dev", devPath, driverPath).CombinedOutput()
+		if err != nil {
+			t.Fatalf("go run failed: %v\n%s", err, out)
+		}
+		if got := string(out); !strings.Contains(got, "docs readme") {
+			t.Errorf("expected /docs/readme.md to resolve against the docs mount, got:\n%s", got)
+		}
+	})
+
+	t.Run("fs.FS", func(t *testing.T) {
+		dir := t.TempDir()
+		outPath := filepath.Join(dir, "assets_vfsdata.go")
+		devPath := filepath.Join(dir, "assets_vfsdata_dev.go")
+		err := vfsgen.Translate(&vfsgen.Config{
+			Input:     input,
+			Output:    outPath,
+			DevOutput: devPath,
+			Package:   "main",
+			FSMode:    true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		driverPath := filepath.Join(dir, "main.go")
+		if err := os.WriteFile(driverPath, []byte(devMountDriverSrcFS), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := exec.Command("go", "run", "-tags", "dev", devPath, driverPath).CombinedOutput()
+		if err != nil {
+			t.Fatalf("go run failed: %v\n%s", err, out)
+		}
+		if got := string(out); !strings.Contains(got, "docs readme") {
+			t.Errorf("expected /docs/readme.md to resolve against the docs mount, got:\n%s", got)
+		}
+	})
+}
+
+const devMountDriverSrc = `package main
+
+import (
+	"fmt"
+	"io"
+)
+
+func main() {
+	f, err := AssetsFS.Open("/docs/readme.md")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(b))
+}
+`
+
+const devMountDriverSrcFS = `package main
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+func main() {
+	b, err := fs.ReadFile(AssetsFS, "docs/readme.md")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(b))
+}
+`
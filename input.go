@@ -0,0 +1,55 @@
+package vfsgen
+
+import (
+	"net/http"
+	pathpkg "path"
+)
+
+// InputConfig describes a single filesystem tree to merge into the
+// generated virtual filesystem.
+type InputConfig struct {
+	// FS is the filesystem to read assets from.
+	FS http.FileSystem
+
+	// Prefix is the path, rooted at "/", under which this tree's assets
+	// are mounted in the generated virtual filesystem. If left empty, it
+	// defaults to "/".
+	Prefix string
+
+	// Recursive, if true, walks into subdirectories of FS. If false,
+	// only the files and directories directly inside FS's root are
+	// included, which is useful for skipping vendored subdirectories.
+	Recursive bool
+
+	// Include, if non-empty, restricts included assets to those whose
+	// base name matches at least one of these path.Match patterns.
+	Include []string
+
+	// Exclude skips assets whose base name matches any of these
+	// path.Match patterns. Exclude is applied after Include.
+	Exclude []string
+}
+
+// includeAsset reports whether the asset with the given base name should
+// be included in the generated filesystem, according to ic.Include and
+// ic.Exclude.
+func (ic *InputConfig) includeAsset(name string) bool {
+	if len(ic.Include) > 0 {
+		included := false
+		for _, pattern := range ic.Include {
+			if ok, _ := pathpkg.Match(pattern, name); ok {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	for _, pattern := range ic.Exclude {
+		if ok, _ := pathpkg.Match(pattern, name); ok {
+			return false
+		}
+	}
+	return true
+}
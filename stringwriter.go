@@ -0,0 +1,26 @@
+package vfsgen
+
+import (
+	"fmt"
+	"io"
+)
+
+// StringWriter is an io.Writer that writes a quoted Go string literal
+// fragment for each byte it receives, suitable for embedding inside a
+// `"..."`-delimited string in generated code.
+type StringWriter struct {
+	io.Writer
+}
+
+// Write implements io.Writer. It writes the Go-escaped representation of
+// p to the underlying writer.
+func (sw StringWriter) Write(p []byte) (n int, err error) {
+	for _, b := range p {
+		_, err = fmt.Fprintf(sw.Writer, "\\x%02x", b)
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}